@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalCacheStore persists cache tars as files on local disk, named by key.
+// It satisfies Cache and is the default store; a future S3-backed Cache can
+// be swapped in via the same interface for multi-instance deployments.
+type LocalCacheStore struct {
+	dir string
+}
+
+// NewLocalCacheStore creates a LocalCacheStore rooted at dir, creating it if
+// it doesn't already exist.
+func NewLocalCacheStore(dir string) (*LocalCacheStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %v", err)
+	}
+	return &LocalCacheStore{dir: dir}, nil
+}
+
+func (s *LocalCacheStore) path(key string) string {
+	return filepath.Join(s.dir, key+".tar")
+}
+
+// Get returns the cached tar for key, or (nil, nil) if no entry exists.
+func (s *LocalCacheStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Put stores r as the tar cached under key, writing to a temp file first so
+// a crash mid-write can't leave a corrupt entry behind.
+func (s *LocalCacheStore) Put(ctx context.Context, key string, r io.Reader) error {
+	tmp, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
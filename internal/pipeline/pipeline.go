@@ -0,0 +1,319 @@
+// Package pipeline runs a language-agnostic, multi-step build+test job
+// (e.g. "pip install" then "pytest") in a single container, with a
+// content-addressed cache of /workspace/.cache persisted between runs so
+// dependency installs aren't repeated on every request.
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/E-Code-AI/e-code/internal/runner"
+)
+
+const cacheDir = "/workspace/.cache"
+
+// Step is one ordered command run inside the pipeline's container.
+type Step struct {
+	Name    string `json:"name"`
+	Cmd     string `json:"cmd"`
+	Timeout int    `json:"timeout,omitempty"` // seconds, 0 = Runner default
+}
+
+// Request describes a pipeline run.
+type Request struct {
+	Language string            `json:"language"`
+	Code     string            `json:"code"`
+	Files    map[string]string `json:"files,omitempty"`
+	Steps    []Step            `json:"steps"`
+	CacheKey string            `json:"cache_key,omitempty"`
+}
+
+// StepResult is the outcome of one Step.
+type StepResult struct {
+	Name       string `json:"name"`
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Result is the outcome of an entire pipeline run.
+type Result struct {
+	Success bool         `json:"success"`
+	Steps   []StepResult `json:"steps"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// Cache stores and retrieves the tar archive of /workspace/.cache for a
+// content-addressed key, shared across pipeline runs with the same
+// cache_key and lockfile contents.
+type Cache interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, error) // nil, nil if absent
+	Put(ctx context.Context, key string, r io.Reader) error
+}
+
+// Runner executes pipeline requests via the Docker Engine API.
+type Runner struct {
+	docker       *client.Client
+	sandboxImage string
+	timeout      time.Duration
+	cache        Cache
+
+	runtime         string // e.g. "runsc" (gVisor), "kata-runtime"; "" = Docker default
+	seccompPath     string
+	apparmorProfile string // "" = Docker default
+}
+
+// NewRunner creates a Runner that uses cache for dependency-install caching,
+// applying the same sandbox hardening (runtime, seccomp, AppArmor) as
+// DockerRunner so pipeline containers aren't a weaker-isolated exception.
+func NewRunner(docker *client.Client, sandboxImage string, timeout time.Duration, cache Cache, runtime, seccompPath, apparmorProfile string) *Runner {
+	return &Runner{
+		docker:          docker,
+		sandboxImage:    sandboxImage,
+		timeout:         timeout,
+		cache:           cache,
+		runtime:         runtime,
+		seccompPath:     seccompPath,
+		apparmorProfile: apparmorProfile,
+	}
+}
+
+// lockfileNames are the dependency-lockfile filenames cacheKeyFor hashes.
+// Only these, not arbitrary source/test files, should invalidate the
+// dependency cache; an edit to the user's code shouldn't force a reinstall.
+var lockfileNames = map[string]bool{
+	"requirements.txt":  true,
+	"Pipfile.lock":      true,
+	"poetry.lock":       true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"go.sum":            true,
+	"go.mod":            true,
+}
+
+// cacheKeyFor hashes the request's cache_key together with the contents of
+// any recognized lockfile among its files, so a dependency change
+// invalidates the cache automatically while a source edit doesn't.
+func cacheKeyFor(req Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.CacheKey))
+
+	names := make([]string, 0, len(req.Files))
+	for name := range req.Files {
+		if lockfileNames[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte(req.Files[name]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// StepFrame is emitted once per step boundary and once per chunk of output,
+// so a caller streaming the response can render progress live.
+type StepFrame struct {
+	Step   string `json:"step"`
+	Stream string `json:"stream,omitempty"` // "stdout" | "stderr"
+	Data   string `json:"data,omitempty"`
+
+	// Present only when this step has finished.
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	DurationMs *int64 `json:"duration_ms,omitempty"`
+}
+
+// Run executes req's steps in order inside one container, stopping at the
+// first non-zero exit, and persists /workspace/.cache back to the cache
+// store for subsequent runs. emit is called for every output chunk and
+// step-boundary frame; pass a no-op to run without streaming.
+func (r *Runner) Run(ctx context.Context, req Request, emit func(StepFrame)) (*Result, error) {
+	if emit == nil {
+		emit = func(StepFrame) {}
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	containerID, err := r.createContainer(execCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.docker.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{Force: true})
+
+	if err := r.seedWorkspace(execCtx, containerID, req); err != nil {
+		return nil, err
+	}
+
+	key := cacheKeyFor(req)
+	if err := r.restoreCache(execCtx, containerID, key); err != nil {
+		return nil, fmt.Errorf("failed to restore cache: %v", err)
+	}
+
+	result := &Result{Success: true}
+	for _, step := range req.Steps {
+		stepResult := r.runStep(execCtx, containerID, step, emit)
+		result.Steps = append(result.Steps, stepResult)
+		if stepResult.ExitCode != 0 {
+			result.Success = false
+			break
+		}
+	}
+
+	if err := r.saveCache(context.Background(), containerID, key); err != nil {
+		// Cache persistence failures shouldn't fail an otherwise
+		// successful pipeline run.
+		result.Error = fmt.Sprintf("cache save failed: %v", err)
+	}
+
+	return result, nil
+}
+
+func (r *Runner) createContainer(ctx context.Context) (string, error) {
+	resp, err := r.docker.ContainerCreate(ctx, &container.Config{
+		Image:      r.sandboxImage,
+		Cmd:        []string{"sleep", "infinity"},
+		WorkingDir: "/workspace",
+		User:       "coderunner",
+	}, &container.HostConfig{
+		// Unlike single-shot executions, pipeline steps routinely need the
+		// network for dependency installs (pip install -r requirements.txt,
+		// npm install, go mod download), so this can't run with
+		// NetworkMode: "none" the way DockerRunner.hostConfig does.
+		Runtime: r.runtime,
+		Resources: container.Resources{
+			Memory:   512 * 1024 * 1024,
+			NanoCPUs: 1_000_000_000,
+		},
+		SecurityOpt: runner.SecurityOpts(r.seccompPath, r.apparmorProfile),
+	}, nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("container create failed: %v", err)
+	}
+	if err := r.docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		r.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("container start failed: %v", err)
+	}
+	return resp.ID, nil
+}
+
+func (r *Runner) seedWorkspace(ctx context.Context, containerID string, req Request) error {
+	files := req.Files
+	if files == nil {
+		files = make(map[string]string)
+	}
+	if req.Code != "" {
+		files[runner.MainFileFor(req.Language)] = req.Code
+	}
+
+	tarData, err := runner.TarFiles(files)
+	if err != nil {
+		return fmt.Errorf("failed to tar workspace files: %v", err)
+	}
+	return r.docker.CopyToContainer(ctx, containerID, "/workspace", tarData, types.CopyToContainerOptions{})
+}
+
+func (r *Runner) restoreCache(ctx context.Context, containerID, key string) error {
+	cached, err := r.cache.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if cached == nil {
+		return nil
+	}
+	defer cached.Close()
+	return r.docker.CopyToContainer(ctx, containerID, "/workspace", cached, types.CopyToContainerOptions{})
+}
+
+func (r *Runner) saveCache(ctx context.Context, containerID, key string) error {
+	reader, _, err := r.docker.CopyFromContainer(ctx, containerID, cacheDir)
+	if err != nil {
+		// No .cache directory yet (nothing installed) isn't an error.
+		return nil
+	}
+	defer reader.Close()
+	return r.cache.Put(ctx, key, reader)
+}
+
+func (r *Runner) runStep(ctx context.Context, containerID string, step Step, emit func(StepFrame)) StepResult {
+	start := time.Now()
+
+	stepCtx := ctx
+	var cancel context.CancelFunc
+	if step.Timeout > 0 {
+		stepCtx, cancel = context.WithTimeout(ctx, time.Duration(step.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	execResp, err := r.docker.ContainerExecCreate(stepCtx, containerID, types.ExecConfig{
+		Cmd:          []string{"sh", "-c", step.Cmd},
+		WorkingDir:   "/workspace",
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return StepResult{Name: step.Name, ExitCode: -1, Stderr: fmt.Sprintf("exec create failed: %v", err)}
+	}
+
+	attach, err := r.docker.ContainerExecAttach(stepCtx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return StepResult{Name: step.Name, ExitCode: -1, Stderr: fmt.Sprintf("exec attach failed: %v", err)}
+	}
+	defer attach.Close()
+
+	var stdout, stderr strings.Builder
+	stdoutW := &emittingWriter{b: &stdout, step: step.Name, stream: "stdout", emit: emit}
+	stderrW := &emittingWriter{b: &stderr, step: step.Name, stream: "stderr", emit: emit}
+	if _, err := stdcopy.StdCopy(stdoutW, stderrW, attach.Reader); err != nil && err != io.EOF {
+		return StepResult{Name: step.Name, ExitCode: -1, Stderr: fmt.Sprintf("reading step output failed: %v", err)}
+	}
+
+	inspect, err := r.docker.ContainerExecInspect(context.Background(), execResp.ID)
+	exitCode := -1
+	if err == nil {
+		exitCode = inspect.ExitCode
+	}
+
+	durationMs := time.Since(start).Milliseconds()
+	emit(StepFrame{Step: step.Name, ExitCode: &exitCode, DurationMs: &durationMs})
+
+	return StepResult{
+		Name:       step.Name,
+		ExitCode:   exitCode,
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		DurationMs: durationMs,
+	}
+}
+
+// emittingWriter accumulates a step's output into b while also emitting each
+// chunk as a StepFrame, so a streaming caller sees output as it's produced.
+type emittingWriter struct {
+	b      *strings.Builder
+	step   string
+	stream string
+	emit   func(StepFrame)
+}
+
+func (w *emittingWriter) Write(p []byte) (int, error) {
+	n, err := w.b.Write(p)
+	if n > 0 {
+		w.emit(StepFrame{Step: w.step, Stream: w.stream, Data: string(p[:n])})
+	}
+	return n, err
+}
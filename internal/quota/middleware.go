@@ -0,0 +1,108 @@
+package quota
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	userIDContextKey contextKey = "quota.userID"
+	limitsContextKey contextKey = "quota.limits"
+)
+
+// UserIDFromContext returns the caller identity the Middleware resolved for
+// this request, if any.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(string)
+	return userID, ok
+}
+
+// LimitsFromContext returns the caller's per-user maxima, so a handler can
+// bound any per-request resource overrides it accepts (memory, CPUs, pids,
+// disk, tmpfs) to what the user is allowed.
+func LimitsFromContext(ctx context.Context) (Limits, bool) {
+	limits, ok := ctx.Value(limitsContextKey).(Limits)
+	return limits, ok
+}
+
+// identifyCaller resolves a stable user ID from the request's Authorization
+// header: the "sub" claim if it looks like a JWT, otherwise a hash of the
+// raw API key. Signature verification happens upstream of this middleware;
+// this only needs a stable identity to key quotas by.
+func identifyCaller(r *http.Request) string {
+	authHeader := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == "" {
+		return "anonymous"
+	}
+
+	if parts := strings.Split(token, "."); len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			var claims struct {
+				Sub string `json:"sub"`
+			}
+			if json.Unmarshal(payload, &claims) == nil && claims.Sub != "" {
+				return claims.Sub
+			}
+		}
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	return "key:" + base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// Middleware enforces per-user concurrency and monthly budget limits in
+// front of next, setting X-RateLimit-*/X-Quota-* response headers and
+// rejecting with 429 when a user is over budget or already at their
+// concurrency limit.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := identifyCaller(r)
+
+		release, status, err := m.Acquire(r.Context(), userID)
+		if err != nil {
+			if IsConcurrencyLimit(err) {
+				writeQuotaHeaders(w, status)
+				http.Error(w, "concurrent execution limit reached", http.StatusTooManyRequests)
+				return
+			}
+			http.Error(w, fmt.Sprintf("quota check failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if release == nil {
+			writeQuotaHeaders(w, status)
+			http.Error(w, "monthly execution budget exhausted", http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+
+		writeQuotaHeaders(w, status)
+		ctx := context.WithValue(r.Context(), userIDContextKey, userID)
+		ctx = context.WithValue(ctx, limitsContextKey, status.Limits)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func writeQuotaHeaders(w http.ResponseWriter, status Status) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limits.MaxConcurrent))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(maxInt(0, status.Limits.MaxConcurrent-status.ConcurrentInUse)))
+	w.Header().Set("X-Quota-CPU-Limit", strconv.FormatFloat(status.Limits.MonthlyCPUSeconds, 'f', 2, 64))
+	w.Header().Set("X-Quota-CPU-Used", strconv.FormatFloat(status.CPUSecondsUsed, 'f', 2, 64))
+	w.Header().Set("X-Quota-Wall-Limit", strconv.FormatFloat(status.Limits.MonthlyWallSeconds, 'f', 2, 64))
+	w.Header().Set("X-Quota-Wall-Used", strconv.FormatFloat(status.WallSecondsUsed, 'f', 2, 64))
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
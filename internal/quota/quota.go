@@ -0,0 +1,153 @@
+// Package quota enforces per-user concurrency limits and monthly CPU/wall
+// budgets in front of the executor's /execute endpoint, backed by an
+// embedded BoltDB store so usage survives restarts without an external
+// database.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limits bounds what a single user may consume, both per-request and over a
+// billing month.
+type Limits struct {
+	MaxConcurrent      int     `json:"max_concurrent"`
+	MonthlyCPUSeconds  float64 `json:"monthly_cpu_seconds"`
+	MonthlyWallSeconds float64 `json:"monthly_wall_seconds"`
+	MaxMemoryMB        int64   `json:"max_memory_mb"`
+	MaxCPUs            float64 `json:"max_cpus"`
+	MaxPids            int     `json:"max_pids"`
+	DiskQuotaMB        int64   `json:"disk_quota_mb"`
+	TmpfsSizeMB        int64   `json:"tmpfs_size_mb"`
+}
+
+// DefaultLimits is applied to any user without an explicit override.
+var DefaultLimits = Limits{
+	MaxConcurrent:      4,
+	MonthlyCPUSeconds:  3600,
+	MonthlyWallSeconds: 7200,
+	MaxMemoryMB:        512,
+	MaxCPUs:            1,
+	MaxPids:            100,
+	DiskQuotaMB:        256,
+	TmpfsSizeMB:        64,
+}
+
+// usage is the running total of a user's current billing month, keyed by
+// "YYYY-MM" so it resets automatically at month boundaries.
+type usage struct {
+	Month       string  `json:"month"`
+	CPUSeconds  float64 `json:"cpu_seconds"`
+	WallSeconds float64 `json:"wall_seconds"`
+}
+
+// Manager enforces concurrency and budget limits for each caller.
+type Manager struct {
+	store Store
+
+	mu    sync.Mutex
+	sems  map[string]chan struct{}
+	inUse map[string]int
+}
+
+// NewManager creates a Manager backed by store.
+func NewManager(store Store) *Manager {
+	return &Manager{
+		store: store,
+		sems:  make(map[string]chan struct{}),
+		inUse: make(map[string]int),
+	}
+}
+
+func (m *Manager) semaphoreFor(userID string, maxConcurrent int) chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sem, ok := m.sems[userID]
+	if !ok {
+		sem = make(chan struct{}, maxConcurrent)
+		m.sems[userID] = sem
+	}
+	return sem
+}
+
+// Status summarizes a user's current standing, used both to populate
+// X-RateLimit-*/X-Quota-* headers and to decide whether a request is
+// rejected with 429.
+type Status struct {
+	Limits          Limits
+	ConcurrentInUse int
+	CPUSecondsUsed  float64
+	WallSecondsUsed float64
+	OverBudget      bool
+}
+
+// Acquire reserves one of the user's concurrency slots and checks their
+// monthly budget. The returned release func must be called exactly once,
+// regardless of whether the request that follows succeeds.
+func (m *Manager) Acquire(ctx context.Context, userID string) (release func(), status Status, err error) {
+	limits, err := m.store.GetLimits(userID)
+	if err != nil {
+		return nil, Status{}, fmt.Errorf("failed to load limits: %v", err)
+	}
+
+	u, err := m.store.GetUsage(userID)
+	if err != nil {
+		return nil, Status{}, fmt.Errorf("failed to load usage: %v", err)
+	}
+
+	status = Status{
+		Limits:          limits,
+		CPUSecondsUsed:  u.CPUSeconds,
+		WallSecondsUsed: u.WallSeconds,
+	}
+	if u.CPUSeconds >= limits.MonthlyCPUSeconds || u.WallSeconds >= limits.MonthlyWallSeconds {
+		status.OverBudget = true
+		return nil, status, nil
+	}
+
+	sem := m.semaphoreFor(userID, limits.MaxConcurrent)
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, status, ctx.Err()
+	default:
+		m.mu.Lock()
+		status.ConcurrentInUse = m.inUse[userID]
+		m.mu.Unlock()
+		return nil, status, errConcurrencyLimit
+	}
+
+	m.mu.Lock()
+	m.inUse[userID]++
+	status.ConcurrentInUse = m.inUse[userID]
+	m.mu.Unlock()
+
+	release = func() {
+		m.mu.Lock()
+		m.inUse[userID]--
+		m.mu.Unlock()
+		<-sem
+	}
+	return release, status, nil
+}
+
+// Debit records the CPU-seconds and wall-clock-seconds a completed
+// execution consumed against the user's monthly budget.
+func (m *Manager) Debit(userID string, cpuSeconds, wallSeconds float64) error {
+	return m.store.AddUsage(userID, cpuSeconds, wallSeconds)
+}
+
+var errConcurrencyLimit = fmt.Errorf("concurrent execution limit reached")
+
+// IsConcurrencyLimit reports whether err was returned by Acquire because the
+// user already has MaxConcurrent executions in flight.
+func IsConcurrencyLimit(err error) bool {
+	return err == errConcurrencyLimit
+}
+
+func currentMonth() string {
+	return time.Now().UTC().Format("2006-01")
+}
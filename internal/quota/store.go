@@ -0,0 +1,117 @@
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	limitsBucket = []byte("limits")
+	usageBucket  = []byte("usage")
+)
+
+// Store persists per-user limits and monthly usage.
+type Store interface {
+	GetLimits(userID string) (Limits, error)
+	SetLimits(userID string, limits Limits) error
+	GetUsage(userID string) (usage, error)
+	AddUsage(userID string, cpuSeconds, wallSeconds float64) error
+}
+
+// BoltStore is the embedded BoltDB-backed Store used in production; it
+// needs no external database to track quotas.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens (creating if necessary) a BoltDB file at path.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quota store: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(limitsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(usageBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize quota buckets: %v", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) GetLimits(userID string) (Limits, error) {
+	limits := DefaultLimits
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(limitsBucket).Get([]byte(userID))
+		if raw == nil {
+			return nil
+		}
+		return json.Unmarshal(raw, &limits)
+	})
+	return limits, err
+}
+
+func (s *BoltStore) SetLimits(userID string, limits Limits) error {
+	raw, err := json.Marshal(limits)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(limitsBucket).Put([]byte(userID), raw)
+	})
+}
+
+func (s *BoltStore) GetUsage(userID string) (usage, error) {
+	u := usage{Month: currentMonth()}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(usageBucket).Get([]byte(userID))
+		if raw == nil {
+			return nil
+		}
+		var stored usage
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			return err
+		}
+		if stored.Month == u.Month {
+			u = stored
+		}
+		return nil
+	})
+	return u, err
+}
+
+func (s *BoltStore) AddUsage(userID string, cpuSeconds, wallSeconds float64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(usageBucket)
+		u := usage{Month: currentMonth()}
+
+		if raw := b.Get([]byte(userID)); raw != nil {
+			var stored usage
+			if err := json.Unmarshal(raw, &stored); err == nil && stored.Month == u.Month {
+				u = stored
+			}
+		}
+
+		u.CPUSeconds += cpuSeconds
+		u.WallSeconds += wallSeconds
+
+		raw, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(userID), raw)
+	})
+}
@@ -0,0 +1,269 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DockerRunner executes one request per container via the Docker Engine
+// API, removing the container on exit.
+type DockerRunner struct {
+	docker          *client.Client
+	timeout         time.Duration
+	sandboxImage    string
+	seccompPath     string
+	runtime         string // e.g. "runsc" (gVisor), "kata-runtime"; "" = Docker default
+	apparmorProfile string // "" = Docker default
+}
+
+// NewDockerRunner creates a new DockerRunner instance.
+func NewDockerRunner() (*DockerRunner, error) {
+	docker, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %v", err)
+	}
+	if _, err := docker.ServerVersion(context.Background()); err != nil {
+		return nil, fmt.Errorf("Docker is not available: %v", err)
+	}
+
+	timeoutSec := 30
+	if envTimeout := os.Getenv("SANDBOX_TIMEOUT_SEC"); envTimeout != "" {
+		fmt.Sscanf(envTimeout, "%d", &timeoutSec)
+	}
+
+	sandboxImage := os.Getenv("SANDBOX_IMAGE")
+	if sandboxImage == "" {
+		sandboxImage = "ecode-sandbox:latest"
+	}
+
+	seccompPath := os.Getenv("SECCOMP_PROFILE")
+	if seccompPath == "" {
+		seccompPath = "./seccomp.json"
+	}
+
+	return &DockerRunner{
+		docker:          docker,
+		timeout:         time.Duration(timeoutSec) * time.Second,
+		sandboxImage:    sandboxImage,
+		seccompPath:     seccompPath,
+		runtime:         os.Getenv("SANDBOX_RUNTIME"),
+		apparmorProfile: os.Getenv("SANDBOX_APPARMOR_PROFILE"),
+	}, nil
+}
+
+// hostConfig builds the container's resource limits, applying overrides
+// from req.Resources (already bounded to the caller's quota by the HTTP
+// layer) over the package defaults.
+func (dr *DockerRunner) hostConfig(overrides *ResourceLimits) *container.HostConfig {
+	memoryMB, cpus, pids := int64(512), 1.0, int64(100)
+	var tmpfsMB, diskQuotaMB int64
+
+	if overrides != nil {
+		if overrides.MemoryMB > 0 {
+			memoryMB = overrides.MemoryMB
+		}
+		if overrides.CPUs > 0 {
+			cpus = overrides.CPUs
+		}
+		if overrides.Pids > 0 {
+			pids = overrides.Pids
+		}
+		tmpfsMB = overrides.TmpfsMB
+		diskQuotaMB = overrides.DiskQuotaMB
+	}
+
+	hc := &container.HostConfig{
+		// AutoRemove is left off so Execute can read ContainerStats for
+		// quota debiting before removing the container itself.
+		NetworkMode: "none",
+		Runtime:     dr.runtime,
+		Resources: container.Resources{
+			Memory:    memoryMB * 1024 * 1024,
+			NanoCPUs:  int64(cpus * 1e9),
+			PidsLimit: int64Ptr(pids),
+		},
+		SecurityOpt: SecurityOpts(dr.seccompPath, dr.apparmorProfile),
+	}
+
+	if tmpfsMB > 0 {
+		hc.Tmpfs = map[string]string{"/tmp": fmt.Sprintf("size=%dm", tmpfsMB)}
+	}
+	if diskQuotaMB > 0 {
+		hc.StorageOpt = map[string]string{"size": fmt.Sprintf("%dM", diskQuotaMB)}
+	}
+
+	return hc
+}
+
+// SecurityOpts builds the Docker SecurityOpt list shared by every container
+// that runs untrusted code: no-new-privileges plus, when configured, the
+// seccomp profile's contents and the AppArmor profile name.
+func SecurityOpts(seccompPath, apparmorProfile string) []string {
+	opts := []string{"no-new-privileges"}
+	if profile, err := os.ReadFile(seccompPath); err == nil {
+		opts = append(opts, fmt.Sprintf("seccomp=%s", profile))
+	}
+	if apparmorProfile != "" {
+		opts = append(opts, fmt.Sprintf("apparmor=%s", apparmorProfile))
+	}
+	return opts
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func (dr *DockerRunner) createContainer(ctx context.Context, req ExecutionRequest) (string, error) {
+	mainFile := MainFileFor(req.Language)
+	cmd := CommandFor(req.Language, mainFile)
+
+	resp, err := dr.docker.ContainerCreate(ctx, &container.Config{
+		Image:        dr.sandboxImage,
+		Cmd:          cmd,
+		WorkingDir:   "/workspace",
+		User:         "coderunner",
+		AttachStdout: true,
+		AttachStderr: true,
+	}, dr.hostConfig(req.Resources), nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("container create failed: %v", err)
+	}
+
+	files := req.Files
+	if files == nil {
+		files = make(map[string]string)
+	}
+	files[mainFile] = req.Code
+
+	tarData, err := TarFiles(files)
+	if err != nil {
+		dr.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("failed to tar workspace files: %v", err)
+	}
+	if err := dr.docker.CopyToContainer(ctx, resp.ID, "/workspace", tarData, types.CopyToContainerOptions{}); err != nil {
+		dr.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("failed to copy workspace files: %v", err)
+	}
+
+	return resp.ID, nil
+}
+
+func (dr *DockerRunner) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	startTime := time.Now()
+
+	execCtx, cancel := context.WithTimeout(ctx, dr.timeout)
+	defer cancel()
+
+	containerID, err := dr.createContainer(execCtx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.docker.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{Force: true})
+
+	attach, err := dr.docker.ContainerAttach(execCtx, containerID, types.ContainerAttachOptions{
+		Stream: true, Stdout: true, Stderr: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("container attach failed: %v", err)
+	}
+	defer attach.Close()
+
+	if err := dr.docker.ContainerStart(execCtx, containerID, types.ContainerStartOptions{}); err != nil {
+		return nil, fmt.Errorf("container start failed: %v", err)
+	}
+
+	var stdout, stderr strings.Builder
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&builderWriter{&stdout}, &builderWriter{&stderr}, attach.Reader)
+		copyDone <- err
+	}()
+
+	statusCh, errCh := dr.docker.ContainerWait(execCtx, containerID, container.WaitConditionNotRunning)
+
+	var exitCode int
+	var errorMsg string
+
+	select {
+	case <-execCtx.Done():
+		dr.docker.ContainerKill(context.Background(), containerID, "SIGKILL")
+		errorMsg = "timeout"
+		exitCode = -1
+	case err := <-errCh:
+		errorMsg = err.Error()
+		exitCode = -1
+	case status := <-statusCh:
+		exitCode = int(status.StatusCode)
+	}
+
+	<-copyDone
+
+	var oomKilled bool
+	if inspect, err := dr.docker.ContainerInspect(context.Background(), containerID); err == nil {
+		oomKilled = inspect.State.OOMKilled
+		if errorMsg == "" {
+			exitCode = inspect.State.ExitCode
+		}
+	}
+
+	cpuSeconds, memoryBytes := dr.readStats(context.Background(), containerID)
+
+	return &ExecutionResult{
+		Success:       exitCode == 0,
+		ExitCode:      exitCode,
+		Stdout:        stdout.String(),
+		Stderr:        stderr.String(),
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+		OOMKilled:     oomKilled,
+		Error:         errorMsg,
+		CPUSeconds:    cpuSeconds,
+		MemoryBytes:   memoryBytes,
+	}, nil
+}
+
+// readStats reads a single ContainerStats snapshot for containerID, still
+// possible because Execute leaves AutoRemove off until it has debited
+// usage. Errors are swallowed; quota debiting degrades to zero rather than
+// failing the request.
+func (dr *DockerRunner) readStats(ctx context.Context, containerID string) (cpuSeconds float64, memoryBytes int64) {
+	resp, err := dr.docker.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return 0, 0
+	}
+	defer resp.Body.Close()
+
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0, 0
+	}
+
+	cpuNanos := stats.CPUStats.CPUUsage.TotalUsage
+	cpuSeconds = float64(cpuNanos) / 1e9
+	memoryBytes = int64(stats.MemoryStats.Usage)
+	return cpuSeconds, memoryBytes
+}
+
+func (dr *DockerRunner) Health(ctx context.Context) error {
+	_, err := dr.docker.ServerVersion(ctx)
+	return err
+}
+
+func (dr *DockerRunner) Close() error {
+	return dr.docker.Close()
+}
+
+// builderWriter adapts a strings.Builder into an io.Writer for stdcopy.StdCopy.
+type builderWriter struct {
+	b *strings.Builder
+}
+
+func (bw *builderWriter) Write(p []byte) (int, error) {
+	return bw.b.Write(p)
+}
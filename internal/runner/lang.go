@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MainFileFor returns the conventional entrypoint filename for language.
+func MainFileFor(language string) string {
+	switch strings.ToLower(language) {
+	case "python", "python3":
+		return "main.py"
+	case "javascript", "node", "nodejs":
+		return "main.js"
+	case "java":
+		return "Main.java"
+	case "go":
+		return "main.go"
+	case "bash", "shell":
+		return "script.sh"
+	default:
+		return "main.txt"
+	}
+}
+
+// CommandFor returns the shell command used to run mainFile for language.
+func CommandFor(language, mainFile string) []string {
+	switch strings.ToLower(language) {
+	case "python", "python3":
+		return []string{"python3", mainFile}
+	case "javascript", "node", "nodejs":
+		return []string{"node", mainFile}
+	case "java":
+		className := strings.TrimSuffix(mainFile, ".java")
+		return []string{"sh", "-c", fmt.Sprintf("javac %s && java %s", mainFile, className)}
+	case "go":
+		return []string{"go", "run", mainFile}
+	case "bash", "shell":
+		return []string{"bash", mainFile}
+	default:
+		return []string{"cat", mainFile}
+	}
+}
@@ -0,0 +1,23 @@
+package runner
+
+import "context"
+
+// MockRunner never executes anything; it's the backend used in local dev
+// and CI when no container runtime is available.
+type MockRunner struct{}
+
+// NewMockRunner creates a new MockRunner instance.
+func NewMockRunner() *MockRunner {
+	return &MockRunner{}
+}
+
+func (m *MockRunner) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	return &ExecutionResult{
+		Success: false,
+		Error:   "mock runner: no real container orchestration is available",
+	}, nil
+}
+
+func (m *MockRunner) Health(ctx context.Context) error { return nil }
+
+func (m *MockRunner) Close() error { return nil }
@@ -0,0 +1,258 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const defaultWarmPoolSize = 2
+
+// memoryPressureFraction is the fraction of a warm container's memory limit
+// above which it's destroyed instead of recycled on release: a process that
+// got this close to its cgroup limit once is likely to do so again, and
+// recycling it risks OOM-killing the next request that lands on it.
+const memoryPressureFraction = 0.9
+
+// warmContainer is one pre-started, idling container for a given language.
+type warmContainer struct {
+	id       string
+	language string
+}
+
+// PooledDockerRunner keeps a small pool of pre-warmed, idling containers per
+// language and hands one to each request via docker exec, eliminating the
+// cold-start cost of ContainerCreate/ContainerStart on the hot path.
+// Containers that exit non-zero, or whose memory usage looks unhealthy, are
+// destroyed rather than recycled; the pool replenishes them lazily.
+type PooledDockerRunner struct {
+	*DockerRunner
+
+	poolSize int
+
+	mu   sync.Mutex
+	pool map[string][]*warmContainer // language -> idle containers
+}
+
+// NewPooledDockerRunner creates a new PooledDockerRunner instance.
+func NewPooledDockerRunner() (*PooledDockerRunner, error) {
+	base, err := NewDockerRunner()
+	if err != nil {
+		return nil, err
+	}
+
+	poolSize := defaultWarmPoolSize
+	if envSize := os.Getenv("WARM_POOL_SIZE"); envSize != "" {
+		if n, err := strconv.Atoi(envSize); err == nil && n > 0 {
+			poolSize = n
+		}
+	}
+
+	return &PooledDockerRunner{
+		DockerRunner: base,
+		poolSize:     poolSize,
+		pool:         make(map[string][]*warmContainer),
+	}, nil
+}
+
+// acquire returns an idling container for language, starting a fresh one if
+// the pool for that language is empty.
+func (p *PooledDockerRunner) acquire(ctx context.Context, language string) (*warmContainer, error) {
+	p.mu.Lock()
+	if warm := p.pool[language]; len(warm) > 0 {
+		wc := warm[len(warm)-1]
+		p.pool[language] = warm[:len(warm)-1]
+		p.mu.Unlock()
+		return wc, nil
+	}
+	p.mu.Unlock()
+
+	return p.startWarmContainer(ctx, language)
+}
+
+// startWarmContainer starts a new idling container for language, sleeping
+// in /workspace until handed an exec.
+func (p *PooledDockerRunner) startWarmContainer(ctx context.Context, language string) (*warmContainer, error) {
+	resp, err := p.docker.ContainerCreate(ctx, &container.Config{
+		Image:      p.sandboxImage,
+		Cmd:        []string{"sleep", "infinity"},
+		WorkingDir: "/workspace",
+		User:       "coderunner",
+	}, p.hostConfig(nil), nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create warm container: %v", err)
+	}
+
+	if err := p.docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		p.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return nil, fmt.Errorf("failed to start warm container: %v", err)
+	}
+
+	return &warmContainer{id: resp.ID, language: language}, nil
+}
+
+// release recycles wc back into the pool for reuse, unless destroy is true
+// (non-zero exit or memory pressure), in which case the container is torn
+// down and the pool is replenished in the background.
+func (p *PooledDockerRunner) release(wc *warmContainer, destroy bool) {
+	if destroy {
+		go func() {
+			p.docker.ContainerRemove(context.Background(), wc.id, types.ContainerRemoveOptions{Force: true})
+			if fresh, err := p.startWarmContainer(context.Background(), wc.language); err == nil {
+				p.mu.Lock()
+				p.pool[wc.language] = append(p.pool[wc.language], fresh)
+				p.mu.Unlock()
+			}
+		}()
+		return
+	}
+
+	p.mu.Lock()
+	if len(p.pool[wc.language]) < p.poolSize {
+		p.pool[wc.language] = append(p.pool[wc.language], wc)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+
+	// Pool already at capacity for this language; destroy the spare instead
+	// of leaking it.
+	go p.docker.ContainerRemove(context.Background(), wc.id, types.ContainerRemoveOptions{Force: true})
+}
+
+// wipeWorkspace clears containerID's /workspace before it's handed to a
+// request. Warm containers are recycled across requests, and the pool is
+// keyed only by language, so without this a file left behind by one caller
+// would be readable by the next (possibly a different user's) request.
+func (p *PooledDockerRunner) wipeWorkspace(ctx context.Context, containerID string) error {
+	execResp, err := p.docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          []string{"sh", "-c", "rm -rf /workspace/* /workspace/.[!.]* 2>/dev/null; true"},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("wipe exec create failed: %v", err)
+	}
+
+	attach, err := p.docker.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return fmt.Errorf("wipe exec attach failed: %v", err)
+	}
+	defer attach.Close()
+	io.Copy(io.Discard, attach.Reader)
+
+	inspect, err := p.docker.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return fmt.Errorf("wipe exec inspect failed: %v", err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("wipe exited %d", inspect.ExitCode)
+	}
+	return nil
+}
+
+func (p *PooledDockerRunner) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	startTime := time.Now()
+
+	execCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	wc, err := p.acquire(execCtx, req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.wipeWorkspace(execCtx, wc.id); err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("failed to wipe workspace: %v", err)
+	}
+
+	mainFile := MainFileFor(req.Language)
+	files := req.Files
+	if files == nil {
+		files = make(map[string]string)
+	}
+	files[mainFile] = req.Code
+
+	tarData, err := TarFiles(files)
+	if err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("failed to tar workspace files: %v", err)
+	}
+	if err := p.docker.CopyToContainer(execCtx, wc.id, "/workspace", tarData, types.CopyToContainerOptions{}); err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("failed to copy workspace files: %v", err)
+	}
+
+	execResp, err := p.docker.ContainerExecCreate(execCtx, wc.id, types.ExecConfig{
+		Cmd:          CommandFor(req.Language, mainFile),
+		WorkingDir:   "/workspace",
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("exec create failed: %v", err)
+	}
+
+	attach, err := p.docker.ContainerExecAttach(execCtx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("exec attach failed: %v", err)
+	}
+	defer attach.Close()
+
+	var stdout, stderr strings.Builder
+	if _, err := stdcopy.StdCopy(&builderWriter{&stdout}, &builderWriter{&stderr}, attach.Reader); err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("reading exec output failed: %v", err)
+	}
+
+	inspect, err := p.docker.ContainerExecInspect(execCtx, execResp.ID)
+	if err != nil {
+		p.release(wc, true)
+		return nil, fmt.Errorf("exec inspect failed: %v", err)
+	}
+
+	destroy := inspect.ExitCode != 0 || p.memoryPressure(context.Background(), wc.id)
+	p.release(wc, destroy)
+
+	return &ExecutionResult{
+		Success:       inspect.ExitCode == 0,
+		ExitCode:      inspect.ExitCode,
+		Stdout:        stdout.String(),
+		Stderr:        stderr.String(),
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// memoryPressure reports whether containerID's last reported memory usage
+// is at or above memoryPressureFraction of its limit. Errors are treated as
+// "not under pressure" so a transient stats failure doesn't needlessly
+// destroy an otherwise-healthy warm container.
+func (p *PooledDockerRunner) memoryPressure(ctx context.Context, containerID string) bool {
+	resp, err := p.docker.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return false
+	}
+	if stats.MemoryStats.Limit == 0 {
+		return false
+	}
+	return float64(stats.MemoryStats.Usage)/float64(stats.MemoryStats.Limit) >= memoryPressureFraction
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// RemoteRunner forwards execution requests to an external sandbox service
+// reachable at SANDBOX_SERVICE_URL.
+type RemoteRunner struct {
+	sandboxServiceURL string
+	client            *http.Client
+}
+
+// NewRemoteRunner creates a new RemoteRunner instance.
+func NewRemoteRunner() *RemoteRunner {
+	sandboxURL := os.Getenv("SANDBOX_SERVICE_URL")
+	if sandboxURL == "" {
+		log.Println("WARNING: SANDBOX_SERVICE_URL not set. Code execution will fail.")
+		sandboxURL = "http://localhost:8000"
+	}
+
+	return &RemoteRunner{
+		sandboxServiceURL: sandboxURL,
+		client:            &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (rr *RemoteRunner) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, rr.sandboxServiceURL+"/run", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create forward request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := rr.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox service unavailable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result ExecutionResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode sandbox response: %v", err)
+	}
+
+	return &result, nil
+}
+
+func (rr *RemoteRunner) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rr.sandboxServiceURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := rr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sandbox service unhealthy: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (rr *RemoteRunner) Close() error { return nil }
@@ -0,0 +1,80 @@
+// Package runner defines the pluggable code-execution backend used by the
+// executor services (server/execution, services/go-runtime): a single
+// Runner interface with Mock, Docker, PooledDocker, and Remote
+// implementations, selected at startup via the RUNNER_BACKEND env var.
+package runner
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecutionRequest is the canonical request shape accepted by every Runner
+// implementation.
+type ExecutionRequest struct {
+	Code     string            `json:"code"`
+	Language string            `json:"language"`
+	Files    map[string]string `json:"files,omitempty"`
+	Timeout  int               `json:"timeout,omitempty"`
+
+	// Resources overrides the backend's default container resource
+	// limits. Callers (e.g. the quota middleware) are responsible for
+	// bounding these to what the caller is actually allowed.
+	Resources *ResourceLimits `json:"resources,omitempty"`
+}
+
+// ResourceLimits overrides the default per-container resource caps.
+type ResourceLimits struct {
+	MemoryMB    int64   `json:"memory_mb,omitempty"`
+	CPUs        float64 `json:"cpus,omitempty"`
+	Pids        int64   `json:"pids,omitempty"`
+	DiskQuotaMB int64   `json:"disk_quota_mb,omitempty"`
+	TmpfsMB     int64   `json:"tmpfs_mb,omitempty"`
+}
+
+// ExecutionResult is the canonical result shape returned by every Runner
+// implementation.
+type ExecutionResult struct {
+	Success       bool   `json:"success"`
+	ExitCode      int    `json:"exit_code"`
+	Stdout        string `json:"stdout"`
+	Stderr        string `json:"stderr"`
+	ExecutionTime int64  `json:"execution_time_ms"`
+	OOMKilled     bool   `json:"oom_killed,omitempty"`
+	Error         string `json:"error,omitempty"`
+
+	// CPUSeconds and MemoryBytes report actual resource usage, when the
+	// backend can measure it (DockerRunner reads these from
+	// ContainerStats), for quota debiting.
+	CPUSeconds  float64 `json:"cpu_seconds,omitempty"`
+	MemoryBytes int64   `json:"memory_bytes,omitempty"`
+}
+
+// Runner executes untrusted code in an isolated sandbox. Implementations
+// must be safe for concurrent use.
+type Runner interface {
+	Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error)
+	Health(ctx context.Context) error
+	Close() error
+}
+
+// New constructs the Runner named by backend ("mock", "docker", "pooled",
+// "remote"). An empty backend falls back to defaultBackend.
+func New(backend, defaultBackend string) (Runner, error) {
+	if backend == "" {
+		backend = defaultBackend
+	}
+
+	switch backend {
+	case "mock":
+		return NewMockRunner(), nil
+	case "docker":
+		return NewDockerRunner()
+	case "pooled":
+		return NewPooledDockerRunner()
+	case "remote":
+		return NewRemoteRunner(), nil
+	default:
+		return nil, fmt.Errorf("unknown RUNNER_BACKEND %q", backend)
+	}
+}
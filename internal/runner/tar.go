@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"archive/tar"
+	"bufio"
+	"io"
+	"path"
+)
+
+// tarFileWriter writes individual in-memory files to a tar stream, creating
+// any parent directory entries Docker's CopyToContainer expects.
+type tarFileWriter struct {
+	tw   *tar.Writer
+	seen map[string]bool
+}
+
+func newTarFileWriter(w io.Writer) *tarFileWriter {
+	return &tarFileWriter{tw: tar.NewWriter(w), seen: make(map[string]bool)}
+}
+
+// WriteFile adds name to the tar stream, writing parent directory headers
+// the first time they're seen.
+func (t *tarFileWriter) WriteFile(name string, content []byte) error {
+	dir := path.Dir(name)
+	for dir != "." && dir != "/" && !t.seen[dir] {
+		t.seen[dir] = true
+		if err := t.tw.WriteHeader(&tar.Header{
+			Name:     dir + "/",
+			Typeflag: tar.TypeDir,
+			Mode:     0755,
+		}); err != nil {
+			return err
+		}
+		dir = path.Dir(dir)
+	}
+
+	if err := t.tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(content)
+	return err
+}
+
+func (t *tarFileWriter) Close() error {
+	return t.tw.Close()
+}
+
+// TarFiles packs a set of in-memory files into a tar stream suitable for
+// CopyToContainer, creating parent directories as needed.
+func TarFiles(files map[string]string) (io.Reader, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := newTarFileWriter(pw)
+		defer pw.Close()
+		defer tw.Close()
+
+		for name, content := range files {
+			if err := tw.WriteFile(name, []byte(content)); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return bufio.NewReader(pr), nil
+}
@@ -1,27 +1,63 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gorilla/mux"
+
+	"github.com/E-Code-AI/e-code/internal/pipeline"
+	"github.com/E-Code-AI/e-code/internal/quota"
+	"github.com/E-Code-AI/e-code/internal/runner"
 )
 
 // ExecutionRequest represents a code execution request
 type ExecutionRequest struct {
-	Code     string            `json:"code"`
-	Language string            `json:"language"`
-	Files    map[string]string `json:"files,omitempty"`
-	Timeout  int               `json:"timeout,omitempty"`
+	Code      string                 `json:"code"`
+	Language  string                 `json:"language"`
+	Files     map[string]string      `json:"files,omitempty"`
+	Timeout   int                    `json:"timeout,omitempty"`
+	Resources *runner.ResourceLimits `json:"resources,omitempty"`
+}
+
+// boundToLimits clamps any per-request resource override in req to the
+// caller's per-user maxima, so a request can only ask for less than it's
+// entitled to, never more. Fields the caller left unset (<=0) are left
+// unset rather than bumped up to the max, so a request that only overrides
+// one dimension (e.g. cpus) doesn't implicitly also request the user's
+// maximum disk quota, memory, pids, and tmpfs.
+func boundToLimits(req *runner.ResourceLimits, limits quota.Limits) *runner.ResourceLimits {
+	if req == nil {
+		return nil
+	}
+	bounded := *req
+	if bounded.MemoryMB > limits.MaxMemoryMB {
+		bounded.MemoryMB = limits.MaxMemoryMB
+	}
+	if bounded.CPUs > limits.MaxCPUs {
+		bounded.CPUs = limits.MaxCPUs
+	}
+	if bounded.Pids > int64(limits.MaxPids) {
+		bounded.Pids = int64(limits.MaxPids)
+	}
+	if bounded.DiskQuotaMB > limits.DiskQuotaMB {
+		bounded.DiskQuotaMB = limits.DiskQuotaMB
+	}
+	if bounded.TmpfsMB > limits.TmpfsSizeMB {
+		bounded.TmpfsMB = limits.TmpfsSizeMB
+	}
+	return &bounded
 }
 
 // ExecutionResult represents the result of code execution
@@ -31,21 +67,49 @@ type ExecutionResult struct {
 	Stdout        string `json:"stdout"`
 	Stderr        string `json:"stderr"`
 	ExecutionTime int64  `json:"execution_time_ms"`
+	OOMKilled     bool   `json:"oom_killed,omitempty"`
 	Error         string `json:"error,omitempty"`
 }
 
-// DockerRunner manages container execution using Docker CLI
+// streamFrame is one NDJSON frame written to an /execute/stream response
+type streamFrame struct {
+	Stream string `json:"stream,omitempty"` // "stdout" | "stderr"
+	Data   string `json:"data,omitempty"`
+
+	// Present only on the final frame
+	Exit      *int   `json:"exit,omitempty"`
+	OOM       *bool  `json:"oom,omitempty"`
+	RuntimeMs *int64 `json:"runtime_ms,omitempty"`
+}
+
+// DockerRunner is the executor HTTP service. Plain /execute requests are
+// delegated to a pluggable runner.Runner (selected via RUNNER_BACKEND), while
+// /execute/stream and /sessions/* talk to the Docker Engine API directly
+// since they need attach/resize semantics the generic interface doesn't
+// expose.
 type DockerRunner struct {
-	apiKey       string
-	timeout      time.Duration
-	sandboxImage string
-	seccompPath  string
+	apiKey          string
+	timeout         time.Duration
+	sandboxImage    string
+	seccompPath     string
+	runtime         string // e.g. "runsc" (gVisor), "kata-runtime"; "" = Docker default
+	apparmorProfile string // "" = Docker default
+	docker          *client.Client
+	sessions        *sessionManager
+	execRunner      runner.Runner
+	quotaStore      *quota.BoltStore
+	quota           *quota.Manager
+	pipelines       *pipeline.Runner
 }
 
 // NewDockerRunner creates a new DockerRunner instance
 func NewDockerRunner() (*DockerRunner, error) {
-	// Check if Docker is available
-	if err := exec.Command("docker", "version").Run(); err != nil {
+	docker, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %v", err)
+	}
+
+	if _, err := docker.ServerVersion(context.Background()); err != nil {
 		return nil, fmt.Errorf("Docker is not available: %v", err)
 	}
 
@@ -71,12 +135,44 @@ func NewDockerRunner() (*DockerRunner, error) {
 		seccompPath = "./seccomp.json"
 	}
 
-	return &DockerRunner{
-		apiKey:       apiKey,
-		timeout:      time.Duration(timeoutSec) * time.Second,
-		sandboxImage: sandboxImage,
-		seccompPath:  seccompPath,
-	}, nil
+	execRunner, err := runner.New(os.Getenv("RUNNER_BACKEND"), "docker")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize runner backend: %v", err)
+	}
+
+	quotaDBPath := os.Getenv("QUOTA_DB_PATH")
+	if quotaDBPath == "" {
+		quotaDBPath = "./quota.db"
+	}
+	quotaStore, err := quota.OpenBoltStore(quotaDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quota store: %v", err)
+	}
+
+	pipelineCacheDir := os.Getenv("PIPELINE_CACHE_DIR")
+	if pipelineCacheDir == "" {
+		pipelineCacheDir = "./pipeline-cache"
+	}
+	pipelineCache, err := pipeline.NewLocalCacheStore(pipelineCacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pipeline cache: %v", err)
+	}
+
+	dr := &DockerRunner{
+		apiKey:          apiKey,
+		timeout:         time.Duration(timeoutSec) * time.Second,
+		sandboxImage:    sandboxImage,
+		seccompPath:     seccompPath,
+		runtime:         os.Getenv("SANDBOX_RUNTIME"),
+		apparmorProfile: os.Getenv("SANDBOX_APPARMOR_PROFILE"),
+		docker:          docker,
+		execRunner:      execRunner,
+		quotaStore:      quotaStore,
+		quota:           quota.NewManager(quotaStore),
+	}
+	dr.pipelines = pipeline.NewRunner(docker, sandboxImage, dr.timeout, pipelineCache, dr.runtime, dr.seccompPath, dr.apparmorProfile)
+	dr.sessions = newSessionManager(dr)
+	return dr, nil
 }
 
 // authenticate checks the API key
@@ -128,143 +224,339 @@ func (dr *DockerRunner) getMainFileName(language string) string {
 	}
 }
 
-// executeCode runs the provided code in a secure container using Docker CLI
-func (dr *DockerRunner) executeCode(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
-	startTime := time.Now()
+// hostConfig builds the resource-constrained, network-isolated HostConfig
+// used by streamed executions and interactive sessions.
+func (dr *DockerRunner) hostConfig() *container.HostConfig {
+	hc := &container.HostConfig{
+		// AutoRemove is left off so streamExecution can read ContainerInspect
+		// (OOMKilled, true ExitCode) before the container is reaped; callers
+		// remove the container themselves once they're done with it.
+		NetworkMode: "none",
+		Runtime:     dr.runtime,
+		Resources: container.Resources{
+			Memory:    512 * 1024 * 1024,
+			NanoCPUs:  1_000_000_000,
+			PidsLimit: int64Ptr(100),
+		},
+		SecurityOpt: []string{"no-new-privileges"},
+	}
+
+	if profile, err := os.ReadFile(dr.seccompPath); err == nil {
+		hc.SecurityOpt = append(hc.SecurityOpt, fmt.Sprintf("seccomp=%s", profile))
+	}
+	if dr.apparmorProfile != "" {
+		hc.SecurityOpt = append(hc.SecurityOpt, fmt.Sprintf("apparmor=%s", dr.apparmorProfile))
+	}
+
+	return hc
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// createContainer creates the sandbox container for req and copies its
+// source files into /workspace via CopyToContainer.
+func (dr *DockerRunner) createContainer(ctx context.Context, req ExecutionRequest) (string, error) {
+	mainFile := dr.getMainFileName(req.Language)
+	cmd := dr.getCommand(req.Language, mainFile)
+
+	resp, err := dr.docker.ContainerCreate(ctx, &container.Config{
+		Image:        dr.sandboxImage,
+		Cmd:          cmd,
+		WorkingDir:   "/workspace",
+		User:         "coderunner",
+		Tty:          false,
+		AttachStdout: true,
+		AttachStderr: true,
+	}, dr.hostConfig(), nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("container create failed: %v", err)
+	}
 
-	// Prepare files
 	files := req.Files
 	if files == nil {
 		files = make(map[string]string)
 	}
-
-	// Add main file
-	mainFile := dr.getMainFileName(req.Language)
 	files[mainFile] = req.Code
 
-	// Create temporary directory for files
-	tempDir, err := os.MkdirTemp("", "ecode-execution-*")
+	tarData, err := runner.TarFiles(files)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp dir: %v", err)
+		dr.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("failed to tar workspace files: %v", err)
 	}
-	defer os.RemoveAll(tempDir)
-
-	// Write files to temp directory
-	for filename, content := range files {
-		filePath := filepath.Join(tempDir, filename)
-		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-			return nil, fmt.Errorf("failed to create directory for %s: %v", filename, err)
-		}
-		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-			return nil, fmt.Errorf("failed to write file %s: %v", filename, err)
-		}
+	if err := dr.docker.CopyToContainer(ctx, resp.ID, "/workspace", tarData, types.CopyToContainerOptions{}); err != nil {
+		dr.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("failed to copy workspace files: %v", err)
 	}
 
-	// Prepare Docker command
-	cmd := dr.getCommand(req.Language, mainFile)
-	dockerArgs := []string{
-		"run",
-		"--rm",                                    // Remove container after execution
-		"--network", "none",                       // No network access
-		"--memory", "512m",                        // Memory limit
-		"--cpus", "1",                             // CPU limit
-		"--pids-limit", "100",                     // Process limit
-		"-v", tempDir + ":/workspace",             // Mount code directory
-		"-w", "/workspace",                        // Working directory
-		"--user", "coderunner",                    // Non-root user
-		"--security-opt", "no-new-privileges",     // Security option
-	}
-
-	// Add seccomp profile if available
-	if _, err := os.Stat(dr.seccompPath); err == nil {
-		seccompArg := fmt.Sprintf("seccomp=%s", dr.seccompPath)
-		dockerArgs = append(dockerArgs, "--security-opt", seccompArg)
-	}
-
-	// Add image and command
-	dockerArgs = append(dockerArgs, dr.sandboxImage)
-	dockerArgs = append(dockerArgs, cmd...)
-
-	// Execute with timeout
+	return resp.ID, nil
+}
+
+// streamExecution runs the provided code and writes NDJSON stream frames to
+// w as stdout/stderr arrive, finishing with a frame carrying the exit code.
+func (dr *DockerRunner) streamExecution(ctx context.Context, req ExecutionRequest, w io.Writer, flush func()) error {
+	startTime := time.Now()
+
 	execCtx, cancel := context.WithTimeout(ctx, dr.timeout)
 	defer cancel()
 
-	dockerCmd := exec.CommandContext(execCtx, "docker", dockerArgs...)
+	containerID, err := dr.createContainer(execCtx, req)
+	if err != nil {
+		return err
+	}
+	defer dr.docker.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{Force: true})
+
+	attach, err := dr.docker.ContainerAttach(execCtx, containerID, types.ContainerAttachOptions{
+		Stream: true, Stdout: true, Stderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("container attach failed: %v", err)
+	}
+	defer attach.Close()
+
+	if err := dr.docker.ContainerStart(execCtx, containerID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("container start failed: %v", err)
+	}
+
+	writeFrame := func(frame streamFrame) {
+		enc, _ := json.Marshal(frame)
+		w.Write(append(enc, '\n'))
+		flush()
+	}
 
-	var stdout, stderr bytes.Buffer
-	dockerCmd.Stdout = &stdout
-	dockerCmd.Stderr = &stderr
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(
+			&frameWriter{write: writeFrame, stream: "stdout"},
+			&frameWriter{write: writeFrame, stream: "stderr"},
+			attach.Reader,
+		)
+		copyDone <- err
+	}()
 
-	err = dockerCmd.Run()
+	statusCh, errCh := dr.docker.ContainerWait(execCtx, containerID, container.WaitConditionNotRunning)
 
 	var exitCode int
-	var errorMsg string
+	select {
+	case <-execCtx.Done():
+		dr.docker.ContainerKill(context.Background(), containerID, "SIGKILL")
+		exitCode = -1
+	case err := <-errCh:
+		log.Printf("container wait error: %v", err)
+		exitCode = -1
+	case status := <-statusCh:
+		exitCode = int(status.StatusCode)
+	}
 
-	if err != nil {
-		if execCtx.Err() == context.DeadlineExceeded {
-			errorMsg = "timeout"
-			exitCode = -1
-		} else if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			errorMsg = err.Error()
-			exitCode = -1
+	<-copyDone
+
+	oomKilled := false
+	if inspect, err := dr.docker.ContainerInspect(context.Background(), containerID); err == nil {
+		oomKilled = inspect.State.OOMKilled
+		exitCode = inspect.State.ExitCode
+	}
+
+	runtimeMs := time.Since(startTime).Milliseconds()
+
+	if userID, ok := quota.UserIDFromContext(ctx); ok {
+		cpuSeconds := dr.readCPUSeconds(context.Background(), containerID)
+		wallSeconds := float64(runtimeMs) / 1000
+		if err := dr.quota.Debit(userID, cpuSeconds, wallSeconds); err != nil {
+			log.Printf("Failed to debit quota for %s: %v", userID, err)
 		}
 	}
 
-	return &ExecutionResult{
-		Success:       exitCode == 0,
-		ExitCode:      exitCode,
-		Stdout:        stdout.String(),
-		Stderr:        stderr.String(),
-		ExecutionTime: time.Since(startTime).Milliseconds(),
-		Error:         errorMsg,
-	}, nil
+	writeFrame(streamFrame{Exit: &exitCode, OOM: &oomKilled, RuntimeMs: &runtimeMs})
+	return nil
+}
+
+// readCPUSeconds reads a single ContainerStats snapshot for containerID's
+// cumulative CPU usage, for quota debiting. Errors are swallowed; quota
+// debiting degrades to zero CPU seconds rather than failing the request.
+func (dr *DockerRunner) readCPUSeconds(ctx context.Context, containerID string) float64 {
+	resp, err := dr.docker.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0
+	}
+	return float64(stats.CPUStats.CPUUsage.TotalUsage) / 1e9
+}
+
+// frameWriter adapts an NDJSON frame writer into an io.Writer so it can be
+// used as a stdcopy.StdCopy destination.
+type frameWriter struct {
+	write  func(streamFrame)
+	stream string
+}
+
+func (fw *frameWriter) Write(p []byte) (int, error) {
+	fw.write(streamFrame{Stream: fw.stream, Data: string(p)})
+	return len(p), nil
 }
 
 // handleExecute handles POST /execute requests
 func (dr *DockerRunner) handleExecute(w http.ResponseWriter, r *http.Request) {
-	// Check authentication
 	if !dr.authenticate(r) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	// Parse request
 	var req ExecutionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	// Validate request
 	if req.Code == "" || req.Language == "" {
 		http.Error(w, "Missing code or language", http.StatusBadRequest)
 		return
 	}
 
+	if r.URL.Query().Get("stream") == "1" {
+		dr.handleExecuteStream(w, r, req)
+		return
+	}
+
 	log.Printf("Executing %s code: %.50s...", req.Language, req.Code)
 
-	// Execute code
-	result, err := dr.executeCode(r.Context(), req)
+	resources := req.Resources
+	if limits, ok := quota.LimitsFromContext(r.Context()); ok {
+		resources = boundToLimits(resources, limits)
+	}
+
+	result, err := dr.execRunner.Execute(r.Context(), runner.ExecutionRequest{
+		Code:      req.Code,
+		Language:  req.Language,
+		Files:     req.Files,
+		Timeout:   req.Timeout,
+		Resources: resources,
+	})
 	if err != nil {
 		log.Printf("Execution error: %v", err)
-		result = &ExecutionResult{
-			Success: false,
-			Error:   err.Error(),
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&ExecutionResult{Success: false, Error: err.Error()})
+		return
+	}
+
+	if userID, ok := quota.UserIDFromContext(r.Context()); ok {
+		wallSeconds := float64(result.ExecutionTime) / 1000
+		if err := dr.quota.Debit(userID, result.CPUSeconds, wallSeconds); err != nil {
+			log.Printf("Failed to debit quota for %s: %v", userID, err)
 		}
 	}
 
-	// Return result
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(&ExecutionResult{
+		Success:       result.Success,
+		ExitCode:      result.ExitCode,
+		Stdout:        result.Stdout,
+		Stderr:        result.Stderr,
+		ExecutionTime: result.ExecutionTime,
+		OOMKilled:     result.OOMKilled,
+		Error:         result.Error,
+	})
+}
+
+// handleExecuteStream streams stdout/stderr as NDJSON frames over a chunked
+// HTTP response while the container runs.
+func (dr *DockerRunner) handleExecuteStream(w http.ResponseWriter, r *http.Request, req ExecutionRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Streaming %s code: %.50s...", req.Language, req.Code)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.WriteHeader(http.StatusOK)
+
+	if err := dr.streamExecution(r.Context(), req, w, flusher.Flush); err != nil {
+		log.Printf("Streamed execution error: %v", err)
+		enc, _ := json.Marshal(streamFrame{Stream: "stderr", Data: err.Error()})
+		w.Write(append(enc, '\n'))
+		flusher.Flush()
+	}
+}
+
+// handlePipeline handles POST /pipelines: an ordered list of build/test
+// steps run in a single container, streamed to the caller as NDJSON frames
+// with a boundary frame between steps, with /workspace/.cache persisted
+// across runs that share a cache_key.
+func (dr *DockerRunner) handlePipeline(w http.ResponseWriter, r *http.Request) {
+	if !dr.authenticate(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req pipeline.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Language == "" || len(req.Steps) == 0 {
+		http.Error(w, "Missing language or steps", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Running %s pipeline with %d step(s)", req.Language, len(req.Steps))
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.WriteHeader(http.StatusOK)
+
+	emit := func(frame pipeline.StepFrame) {
+		enc, _ := json.Marshal(frame)
+		w.Write(append(enc, '\n'))
+		flusher.Flush()
+	}
+
+	result, err := dr.pipelines.Run(r.Context(), req, emit)
+	if err != nil {
+		log.Printf("Pipeline run error: %v", err)
+		enc, _ := json.Marshal(pipeline.StepFrame{Stream: "stderr", Data: err.Error()})
+		w.Write(append(enc, '\n'))
+		flusher.Flush()
+		return
+	}
+
+	enc, _ := json.Marshal(result)
+	w.Write(append(enc, '\n'))
+	flusher.Flush()
+}
+
+// sandboxRuntime and sandboxAppArmorProfile report the effective values,
+// falling back to what the Docker daemon itself defaults to when unset, so
+// operators can confirm the hardened path is actually in use.
+func (dr *DockerRunner) sandboxRuntime() string {
+	if dr.runtime == "" {
+		return "runc"
+	}
+	return dr.runtime
+}
+
+func (dr *DockerRunner) sandboxAppArmorProfile() string {
+	if dr.apparmorProfile == "" {
+		return "docker-default"
+	}
+	return dr.apparmorProfile
 }
 
 // handleHealth handles GET /health requests
 func (dr *DockerRunner) handleHealth(w http.ResponseWriter, r *http.Request) {
-	// Check Docker connectivity
-	cmd := exec.Command("docker", "version")
-	if err := cmd.Run(); err != nil {
+	if err := dr.execRunner.Health(r.Context()); err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(map[string]string{
 			"status": "unhealthy",
@@ -275,34 +567,43 @@ func (dr *DockerRunner) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-		"image":  dr.sandboxImage,
+		"status":   "healthy",
+		"image":    dr.sandboxImage,
+		"runtime":  dr.sandboxRuntime(),
+		"apparmor": dr.sandboxAppArmorProfile(),
+		"seccomp":  dr.seccompPath,
 	})
 }
 
 func main() {
-	// Initialize Docker runner
-	runner, err := NewDockerRunner()
+	executor, err := NewDockerRunner()
 	if err != nil {
 		log.Fatalf("Failed to initialize Docker runner: %v", err)
 	}
+	defer executor.docker.Close()
+	defer executor.execRunner.Close()
+	defer executor.quotaStore.Close()
 
-	// Setup routes
 	r := mux.NewRouter()
-	r.HandleFunc("/execute", runner.handleExecute).Methods("POST")
-	r.HandleFunc("/health", runner.handleHealth).Methods("GET")
+	r.Handle("/execute", executor.quota.Middleware(http.HandlerFunc(executor.handleExecute))).Methods("POST")
+	r.HandleFunc("/pipelines", executor.handlePipeline).Methods("POST")
+	r.HandleFunc("/health", executor.handleHealth).Methods("GET")
+	r.HandleFunc("/sessions", executor.handleCreateSession).Methods("POST")
+	r.HandleFunc("/sessions/{id}/attach", executor.handleAttachSession).Methods("GET")
+	r.HandleFunc("/sessions/{id}/resize", executor.handleResizeSession).Methods("POST")
+	r.HandleFunc("/sessions/{id}/exec", executor.handleSessionExec).Methods("POST")
+	r.HandleFunc("/sessions/{id}", executor.handleDeleteSession).Methods("DELETE")
 
-	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
 	log.Printf("Starting executor service on port %s", port)
-	log.Printf("Sandbox image: %s", runner.sandboxImage)
-	log.Printf("Timeout: %v", runner.timeout)
+	log.Printf("Sandbox image: %s", executor.sandboxImage)
+	log.Printf("Timeout: %v", executor.timeout)
 
 	if err := http.ListenAndServe(":"+port, r); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/E-Code-AI/e-code/internal/runner"
+)
+
+const sessionIdleTimeout = 15 * time.Minute
+
+// session is a long-lived TTY container backing the IDE terminal panel.
+type session struct {
+	id           string
+	containerID  string
+	lastActivity time.Time
+
+	mu sync.Mutex
+}
+
+func (s *session) touch() {
+	s.mu.Lock()
+	s.lastActivity = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *session) idleSince() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastActivity)
+}
+
+// sessionManager tracks active interactive sessions and reaps idle ones.
+type sessionManager struct {
+	docker   *DockerRunner
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func newSessionManager(dr *DockerRunner) *sessionManager {
+	sm := &sessionManager{docker: dr, sessions: make(map[string]*session)}
+	go sm.reapLoop()
+	return sm
+}
+
+func (sm *sessionManager) reapLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		sm.reapIdle()
+	}
+}
+
+func (sm *sessionManager) reapIdle() {
+	sm.mu.Lock()
+	var toRemove []*session
+	for id, s := range sm.sessions {
+		if s.idleSince() > sessionIdleTimeout {
+			toRemove = append(toRemove, s)
+			delete(sm.sessions, id)
+		}
+	}
+	sm.mu.Unlock()
+
+	for _, s := range toRemove {
+		log.Printf("Reaping idle session %s (container %s)", s.id, s.containerID)
+		sm.docker.docker.ContainerRemove(context.Background(), s.containerID, types.ContainerRemoveOptions{Force: true})
+	}
+}
+
+func (sm *sessionManager) get(id string) (*session, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	s, ok := sm.sessions[id]
+	return s, ok
+}
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// handleCreateSession handles POST /sessions, starting a long-lived TTY
+// container for the requested language.
+func (dr *DockerRunner) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	if !dr.authenticate(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	resp, err := dr.docker.ContainerCreate(ctx, &container.Config{
+		Image:        dr.sandboxImage,
+		Cmd:          []string{"sh"},
+		WorkingDir:   "/workspace",
+		User:         "coderunner",
+		Tty:          true,
+		OpenStdin:    true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}, dr.hostConfig(), nil, nil, "")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create session container: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := dr.docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		dr.docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		http.Error(w, fmt.Sprintf("failed to start session container: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s := &session{id: uuid.NewString(), containerID: resp.ID, lastActivity: time.Now()}
+	dr.sessions.mu.Lock()
+	dr.sessions.sessions[s.id] = s
+	dr.sessions.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"session_id": s.id})
+}
+
+// handleAttachSession upgrades to a WebSocket and proxies it bidirectionally
+// onto the session container's TTY.
+func (dr *DockerRunner) handleAttachSession(w http.ResponseWriter, r *http.Request) {
+	if !dr.authenticate(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := mux.Vars(r)["id"]
+	s, ok := dr.sessions.get(sessionID)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	attach, err := dr.docker.ContainerAttach(r.Context(), s.containerID, types.ContainerAttachOptions{
+		Stream: true, Stdin: true, Stdout: true, Stderr: true,
+	})
+	if err != nil {
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("attach failed: %v", err)))
+		return
+	}
+	defer attach.Close()
+
+	done := make(chan struct{})
+
+	// Container -> client
+	go func() {
+		defer close(done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := attach.Reader.Read(buf)
+			if n > 0 {
+				s.touch()
+				if werr := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Client -> container
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if msgType != websocket.BinaryMessage && msgType != websocket.TextMessage {
+			continue
+		}
+		s.touch()
+		if _, err := attach.Conn.Write(data); err != nil {
+			break
+		}
+	}
+
+	<-done
+}
+
+// resizeRequest is the body of POST /sessions/{id}/resize.
+type resizeRequest struct {
+	Width  uint `json:"width"`
+	Height uint `json:"height"`
+}
+
+// handleResizeSession handles POST /sessions/{id}/resize.
+func (dr *DockerRunner) handleResizeSession(w http.ResponseWriter, r *http.Request) {
+	if !dr.authenticate(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := mux.Vars(r)["id"]
+	s, ok := dr.sessions.get(sessionID)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	var req resizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	s.touch()
+	err := dr.docker.ContainerResize(r.Context(), s.containerID, types.ResizeOptions{
+		Width:  req.Width,
+		Height: req.Height,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("resize failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteSession handles DELETE /sessions/{id}.
+func (dr *DockerRunner) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	if !dr.authenticate(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := mux.Vars(r)["id"]
+	dr.sessions.mu.Lock()
+	s, ok := dr.sessions.sessions[sessionID]
+	if ok {
+		delete(dr.sessions.sessions, sessionID)
+	}
+	dr.sessions.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if err := dr.docker.ContainerRemove(r.Context(), s.containerID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		log.Printf("failed to remove session container %s: %v", s.containerID, err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSessionExec handles POST /sessions/{id}/exec, running one additional
+// command inside the session's existing container.
+func (dr *DockerRunner) handleSessionExec(w http.ResponseWriter, r *http.Request) {
+	if !dr.authenticate(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := mux.Vars(r)["id"]
+	s, ok := dr.sessions.get(sessionID)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	var req ExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	s.touch()
+	ctx := r.Context()
+
+	mainFile := dr.getMainFileName(req.Language)
+	files := req.Files
+	if files == nil {
+		files = make(map[string]string)
+	}
+	files[mainFile] = req.Code
+
+	tarData, err := runner.TarFiles(files)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to tar workspace files: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := dr.docker.CopyToContainer(ctx, s.containerID, "/workspace", tarData, types.CopyToContainerOptions{}); err != nil {
+		http.Error(w, fmt.Sprintf("failed to copy workspace files: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	execResp, err := dr.docker.ContainerExecCreate(ctx, s.containerID, types.ExecConfig{
+		Cmd:          dr.getCommand(req.Language, mainFile),
+		WorkingDir:   "/workspace",
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("exec create failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	attach, err := dr.docker.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("exec attach failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer attach.Close()
+
+	var stdout, stderr strings.Builder
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, attach.Reader); err != nil {
+		http.Error(w, fmt.Sprintf("reading exec output failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	inspect, err := dr.docker.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("exec inspect failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&ExecutionResult{
+		Success:  inspect.ExitCode == 0,
+		ExitCode: inspect.ExitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	})
+}
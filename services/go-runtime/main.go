@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"os"
 	"time"
+
+	"github.com/E-Code-AI/e-code/internal/runner"
 )
 
 type healthResponse struct {
@@ -26,19 +29,53 @@ type mockResponse struct {
 }
 
 func main() {
+	execRunner, err := runner.New(os.Getenv("RUNNER_BACKEND"), "mock")
+	if err != nil {
+		log.Fatalf("[GO-RUNTIME] Failed to initialize runner backend: %v", err)
+	}
+	defer execRunner.Close()
+
+	_, mock := execRunner.(*runner.MockRunner)
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		message := "Go runtime service running in mock mode. No real container orchestration is available."
+		if !mock {
+			message = "Go runtime service running against a real runner backend."
+		}
 		respondJSON(w, http.StatusOK, healthResponse{
 			Status:    "healthy",
 			Service:   "go-runtime",
 			Port:      8080,
-			Mock:      true,
-			Message:   "Go runtime service running in mock mode. No real container orchestration is available.",
+			Mock:      mock,
+			Message:   message,
 			Timestamp: time.Now().UTC(),
 		})
 	})
 
+	mux.HandleFunc("/execute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req runner.ExecutionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		result, err := execRunner.Execute(r.Context(), req)
+		if err != nil {
+			log.Printf("[GO-RUNTIME] Execution error: %v", err)
+			respondJSON(w, http.StatusInternalServerError, runner.ExecutionResult{Success: false, Error: err.Error()})
+			return
+		}
+
+		respondJSON(w, http.StatusOK, result)
+	})
+
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[GO-RUNTIME] Received %s %s while in mock mode", r.Method, r.URL.Path)
 		respondJSON(w, http.StatusNotImplemented, mockResponse{
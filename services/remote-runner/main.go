@@ -1,43 +1,44 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/E-Code-AI/e-code/internal/runner"
 )
 
 // ExecutionRequest represents a request to execute code
 type ExecutionRequest struct {
-	Language string            `json:"language"`
-	Code     string            `json:"code"`
-	Input    string            `json:"input,omitempty"`
-	Files    map[string]string `json:"files,omitempty"`
+	Language string                 `json:"language"`
+	Code     string                 `json:"code"`
+	Input    string                 `json:"input,omitempty"`
+	Files    map[string]string      `json:"files,omitempty"`
 	Options  map[string]interface{} `json:"options,omitempty"`
 }
 
 // ExecutionResponse represents the response from code execution
 type ExecutionResponse struct {
-	Success   bool   `json:"success"`
-	Output    string `json:"output"`
-	Error     string `json:"error,omitempty"`
-	ExitCode  int    `json:"exitCode"`
-	Runtime   int64  `json:"runtime"`
-	MemoryUsed int64 `json:"memoryUsed,omitempty"`
+	Success    bool   `json:"success"`
+	Output     string `json:"output"`
+	Error      string `json:"error,omitempty"`
+	ExitCode   int    `json:"exitCode"`
+	Runtime    int64  `json:"runtime"`
+	MemoryUsed int64  `json:"memoryUsed,omitempty"`
 }
 
-// RemoteRunner forwards execution requests to external sandbox service
+// RemoteRunner forwards execution requests to an external sandbox service
+// via the pluggable runner.Runner interface.
 type RemoteRunner struct {
 	sandboxServiceURL string
-	client           *http.Client
+	execRunner        runner.Runner
 }
 
 // NewRemoteRunner creates a new remote runner instance
-func NewRemoteRunner() *RemoteRunner {
+func NewRemoteRunner() (*RemoteRunner, error) {
 	sandboxURL := os.Getenv("SANDBOX_SERVICE_URL")
 	if sandboxURL == "" {
 		log.Println("⚠️  WARNING: SANDBOX_SERVICE_URL not set. Code execution will fail.")
@@ -45,12 +46,15 @@ func NewRemoteRunner() *RemoteRunner {
 		sandboxURL = "http://localhost:8000" // Fallback URL
 	}
 
+	execRunner, err := runner.New(os.Getenv("RUNNER_BACKEND"), "remote")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize runner backend: %v", err)
+	}
+
 	return &RemoteRunner{
 		sandboxServiceURL: sandboxURL,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
+		execRunner:        execRunner,
+	}, nil
 }
 
 // handleRun forwards execution requests to the external sandbox service
@@ -71,66 +75,38 @@ func (rr *RemoteRunner) handleRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Read the request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		log.Printf("Error reading request body: %v", err)
-		http.Error(w, "Failed to read request", http.StatusBadRequest)
-		return
-	}
-	defer r.Body.Close()
-
-	// Parse the execution request
 	var execReq ExecutionRequest
-	if err := json.Unmarshal(body, &execReq); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&execReq); err != nil {
 		log.Printf("Error parsing execution request: %v", err)
 		http.Error(w, "Invalid request format", http.StatusBadRequest)
 		return
 	}
+	defer r.Body.Close()
 
 	log.Printf("🔄 Forwarding %s execution request to sandbox service", execReq.Language)
 
-	// Forward the request to the sandbox service
-	forwardURL := fmt.Sprintf("%s/run", rr.sandboxServiceURL)
-	req, err := http.NewRequest("POST", forwardURL, bytes.NewBuffer(body))
-	if err != nil {
-		log.Printf("Error creating forward request: %v", err)
-		rr.sendErrorResponse(w, "Failed to create forward request", 500)
-		return
-	}
-
-	// Copy headers from original request
-	req.Header.Set("Content-Type", "application/json")
-	for name, values := range r.Header {
-		for _, value := range values {
-			req.Header.Add(name, value)
-		}
-	}
-
-	// Make the request to sandbox service
 	startTime := time.Now()
-	resp, err := rr.client.Do(req)
+	result, err := rr.execRunner.Execute(r.Context(), runner.ExecutionRequest{
+		Code:     execReq.Code,
+		Language: execReq.Language,
+		Files:    execReq.Files,
+	})
 	duration := time.Since(startTime)
 
 	if err != nil {
 		log.Printf("Error forwarding to sandbox service: %v", err)
-		rr.sendErrorResponse(w, fmt.Sprintf("Sandbox service unavailable: %v", err), 503)
-		return
-	}
-	defer resp.Body.Close()
-
-	// Read the response
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading sandbox response: %v", err)
-		rr.sendErrorResponse(w, "Failed to read sandbox response", 502)
+		rr.sendErrorResponse(w, fmt.Sprintf("Sandbox service unavailable: %v", err), http.StatusServiceUnavailable)
 		return
 	}
 
-	// Forward the response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(resp.StatusCode)
-	w.Write(responseBody)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExecutionResponse{
+		Success:  result.Success,
+		Output:   result.Stdout,
+		Error:    result.Error,
+		ExitCode: result.ExitCode,
+		Runtime:  result.ExecutionTime,
+	})
 
 	log.Printf("✅ Request forwarded successfully in %v", duration)
 }
@@ -153,7 +129,7 @@ func (rr *RemoteRunner) sendErrorResponse(w http.ResponseWriter, message string,
 // handleHealth provides a health check endpoint
 func (rr *RemoteRunner) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	
+
 	health := map[string]interface{}{
 		"status":            "ok",
 		"service":           "remote-runner",
@@ -161,36 +137,27 @@ func (rr *RemoteRunner) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"timestamp":         time.Now().Unix(),
 	}
 
-	// Test connection to sandbox service
-	if rr.sandboxServiceURL != "http://localhost:8000" {
-		testReq, err := http.NewRequest("GET", fmt.Sprintf("%s/health", rr.sandboxServiceURL), nil)
-		if err == nil {
-			testReq.Header.Set("User-Agent", "e-code-remote-runner/1.0")
-			resp, err := rr.client.Do(testReq)
-			if err != nil {
-				health["sandboxStatus"] = "unreachable"
-				health["sandboxError"] = err.Error()
-			} else {
-				resp.Body.Close()
-				health["sandboxStatus"] = "connected"
-				health["sandboxStatusCode"] = resp.StatusCode
-			}
-		}
+	if err := rr.execRunner.Health(r.Context()); err != nil {
+		health["sandboxStatus"] = "unreachable"
+		health["sandboxError"] = err.Error()
 	} else {
-		health["sandboxStatus"] = "not_configured"
-		health["warning"] = "SANDBOX_SERVICE_URL not set"
+		health["sandboxStatus"] = "connected"
 	}
 
 	json.NewEncoder(w).Encode(health)
 }
 
 func main() {
-	runner := NewRemoteRunner()
+	runner, err := NewRemoteRunner()
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize remote runner: %v", err)
+	}
+	defer runner.execRunner.Close()
 
 	// Setup routes
 	http.HandleFunc("/run", runner.handleRun)
 	http.HandleFunc("/health", runner.handleHealth)
-	
+
 	// Root endpoint with service information
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -218,4 +185,4 @@ func main() {
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatalf("❌ Failed to start server: %v", err)
 	}
-}
\ No newline at end of file
+}